@@ -66,6 +66,143 @@ func PublicKeyToBytes(pub *rsa.PublicKey) ([]byte, error) {
 	return pubBytes, nil
 }
 
+// PrivateKeyToBytesWithPassword private key to bytes, encrypting the PEM
+// block with AES-256 under the given password. An empty password produces
+// the same output as PrivateKeyToBytes.
+func PrivateKeyToBytesWithPassword(priv *rsa.PrivateKey, password string) ([]byte, error) {
+	if password == "" {
+		return PrivateKeyToBytes(priv), nil
+	}
+
+	var err error
+	var block *pem.Block
+
+	if block, err = x509.EncryptPEMBlock(
+		rand.Reader,
+		"RSA PRIVATE KEY",
+		x509.MarshalPKCS1PrivateKey(priv),
+		[]byte(password),
+		x509.PEMCipherAES256,
+	); err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
+
+// BytesToPrivateKeyWithPassword bytes to private key, transparently
+// decrypting the PEM block with the given password if it is encrypted.
+func BytesToPrivateKeyWithPassword(data []byte, password string) (*rsa.PrivateKey, error) {
+	var err error
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+
+	b := block.Bytes
+
+	if x509.IsEncryptedPEMBlock(block) == true {
+		if b, err = x509.DecryptPEMBlock(block, []byte(password)); err != nil {
+			return nil, err
+		}
+	}
+
+	//
+	var privKey *rsa.PrivateKey
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		// pkcs1
+		if privKey, err = x509.ParsePKCS1PrivateKey(b); err != nil {
+			return nil, err
+		}
+	case "PRIVATE KEY":
+		// pkcs8
+		var ifc interface{}
+		var ok bool
+
+		if ifc, err = x509.ParsePKCS8PrivateKey(b); err != nil {
+			return nil, err
+		}
+
+		if privKey, ok = ifc.(*rsa.PrivateKey); !ok {
+			return nil, fmt.Errorf("Failed to type assertion to *rsa.PrivateKey")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported %s block.Type", block.Type)
+	}
+
+	return privKey, nil
+}
+
+// PublicKeyToBytesWithPassword public key to bytes, encrypting the PEM
+// block with AES-256 under the given password. An empty password produces
+// the same output as PublicKeyToBytes.
+func PublicKeyToBytesWithPassword(pub *rsa.PublicKey, password string) ([]byte, error) {
+	if password == "" {
+		return PublicKeyToBytes(pub)
+	}
+
+	var err error
+	var pubASN1 []byte
+
+	if pubASN1, err = x509.MarshalPKIXPublicKey(pub); err != nil {
+		return nil, err
+	}
+
+	//
+	var block *pem.Block
+
+	if block, err = x509.EncryptPEMBlock(
+		rand.Reader,
+		"RSA PUBLIC KEY",
+		pubASN1,
+		[]byte(password),
+		x509.PEMCipherAES256,
+	); err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
+
+// BytesToPublicKeyWithPassword bytes to public key, transparently
+// decrypting the PEM block with the given password if it is encrypted.
+func BytesToPublicKeyWithPassword(data []byte, password string) (*rsa.PublicKey, error) {
+	var err error
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+
+	b := block.Bytes
+
+	if x509.IsEncryptedPEMBlock(block) == true {
+		if b, err = x509.DecryptPEMBlock(block, []byte(password)); err != nil {
+			return nil, err
+		}
+	}
+
+	//
+	var ifc interface{}
+
+	if ifc, err = x509.ParsePKIXPublicKey(b); err != nil {
+		return nil, err
+	}
+
+	//
+	var pubKey *rsa.PublicKey
+	var ok bool
+
+	if pubKey, ok = ifc.(*rsa.PublicKey); !ok {
+		return nil, fmt.Errorf("Failed to type assert to *rsa.PublicKey")
+	}
+
+	return pubKey, nil
+}
+
 // BytesToPrivateKey bytes to private key
 func BytesToPrivateKey(data []byte) (*rsa.PrivateKey, error) {
 	var err error
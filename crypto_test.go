@@ -0,0 +1,89 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrivateKeyBytesWithPasswordRoundTrip(t *testing.T) {
+	priv, _, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	encoded, err := PrivateKeyToBytesWithPassword(priv, "hunter2")
+	if err != nil {
+		t.Fatalf("PrivateKeyToBytesWithPassword: %v", err)
+	}
+
+	decoded, err := BytesToPrivateKeyWithPassword(encoded, "hunter2")
+	if err != nil {
+		t.Fatalf("BytesToPrivateKeyWithPassword: %v", err)
+	}
+
+	if !decoded.Equal(priv) {
+		t.Fatalf("round trip produced a different private key")
+	}
+}
+
+func TestPrivateKeyBytesWithPasswordWrongPassword(t *testing.T) {
+	priv, _, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	encoded, err := PrivateKeyToBytesWithPassword(priv, "correct")
+	if err != nil {
+		t.Fatalf("PrivateKeyToBytesWithPassword: %v", err)
+	}
+
+	if _, err := BytesToPrivateKeyWithPassword(encoded, "wrong"); err == nil {
+		t.Fatalf("BytesToPrivateKeyWithPassword with wrong password: got nil error, want error")
+	}
+}
+
+func TestPrivateKeyBytesWithEmptyPasswordMatchesPrivateKeyToBytes(t *testing.T) {
+	priv, _, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	encoded, err := PrivateKeyToBytesWithPassword(priv, "")
+	if err != nil {
+		t.Fatalf("PrivateKeyToBytesWithPassword: %v", err)
+	}
+
+	if !bytes.Equal(encoded, PrivateKeyToBytes(priv)) {
+		t.Fatalf("empty-password output differs from PrivateKeyToBytes")
+	}
+
+	decoded, err := BytesToPrivateKey(encoded)
+	if err != nil {
+		t.Fatalf("BytesToPrivateKey: %v", err)
+	}
+
+	if !decoded.Equal(priv) {
+		t.Fatalf("empty-password round trip produced a different private key")
+	}
+}
+
+func TestPublicKeyBytesWithPasswordRoundTrip(t *testing.T) {
+	_, pub, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	encoded, err := PublicKeyToBytesWithPassword(pub, "hunter2")
+	if err != nil {
+		t.Fatalf("PublicKeyToBytesWithPassword: %v", err)
+	}
+
+	decoded, err := BytesToPublicKeyWithPassword(encoded, "hunter2")
+	if err != nil {
+		t.Fatalf("BytesToPublicKeyWithPassword: %v", err)
+	}
+
+	if !decoded.Equal(pub) {
+		t.Fatalf("round trip produced a different public key")
+	}
+}
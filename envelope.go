@@ -0,0 +1,151 @@
+package util
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"io"
+)
+
+// envelopeMagic identifies the binary framing produced by EncryptEnvelope.
+const envelopeMagic = "UTLE"
+
+// envelopeVersion is the current envelope framing version.
+const envelopeVersion byte = 1
+
+// EncryptEnvelope encrypts msg of arbitrary size for the recipient pub using
+// hybrid RSA+AES envelope encryption: a random 32-byte AES-256 key seals the
+// payload with AES-GCM, and the AES key itself is wrapped with RSA-OAEP-SHA256
+// under pub. The output framing is:
+//
+//	4 bytes  magic ("UTLE")
+//	1 byte   version
+//	2 bytes  wrapped-key length (big-endian)
+//	N bytes  RSA-OAEP-SHA256 wrapped AES key
+//	rest     nonce || ciphertext||tag (AES-GCM sealed payload)
+func EncryptEnvelope(msg []byte, pub *rsa.PublicKey) ([]byte, error) {
+	var err error
+
+	// generate a random 32-byte AES-256 key
+	aesKey := make([]byte, 32)
+
+	if _, err = io.ReadFull(rand.Reader, aesKey); err != nil {
+		return nil, err
+	}
+
+	//
+	var block cipher.Block
+
+	if block, err = aes.NewCipher(aesKey); err != nil {
+		return nil, err
+	}
+
+	//
+	var gcm cipher.AEAD
+
+	if gcm, err = cipher.NewGCM(block); err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, msg, nil)
+
+	// wrap the AES key with RSA-OAEP-SHA256
+	var wrappedKey []byte
+
+	if wrappedKey, err = rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, aesKey, nil); err != nil {
+		return nil, err
+	}
+
+	if len(wrappedKey) > 0xFFFF {
+		return nil, fmt.Errorf("wrapped key too large: %d bytes", len(wrappedKey))
+	}
+
+	//
+	header := []byte(envelopeMagic)
+	header = append(header, envelopeVersion)
+	header = append(header, byte(len(wrappedKey)>>8), byte(len(wrappedKey)))
+
+	out := append(header, wrappedKey...)
+	out = append(out, sealed...)
+
+	return out, nil
+}
+
+// DecryptEnvelope reverses EncryptEnvelope: it parses the framing, unwraps
+// the AES key with priv, and opens the AES-GCM sealed payload.
+func DecryptEnvelope(ct []byte, priv *rsa.PrivateKey) ([]byte, error) {
+	if len(ct) < len(envelopeMagic)+1+2 {
+		return nil, fmt.Errorf("envelope too short")
+	}
+
+	if string(ct[:len(envelopeMagic)]) != envelopeMagic {
+		return nil, fmt.Errorf("unrecognized envelope magic")
+	}
+
+	pos := len(envelopeMagic)
+
+	version := ct[pos]
+	pos++
+
+	if version != envelopeVersion {
+		return nil, fmt.Errorf("unsupported envelope version %d", version)
+	}
+
+	wrappedKeyLen := int(ct[pos])<<8 | int(ct[pos+1])
+	pos += 2
+
+	if len(ct) < pos+wrappedKeyLen {
+		return nil, fmt.Errorf("envelope truncated before wrapped key")
+	}
+
+	wrappedKey := ct[pos : pos+wrappedKeyLen]
+	pos += wrappedKeyLen
+
+	//
+	var err error
+	var aesKey []byte
+
+	if aesKey, err = rsa.DecryptOAEP(sha256.New(), rand.Reader, priv, wrappedKey, nil); err != nil {
+		return nil, err
+	}
+
+	//
+	var block cipher.Block
+
+	if block, err = aes.NewCipher(aesKey); err != nil {
+		return nil, err
+	}
+
+	//
+	var gcm cipher.AEAD
+
+	if gcm, err = cipher.NewGCM(block); err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+	rest := ct[pos:]
+
+	if len(rest) < nonceSize {
+		return nil, fmt.Errorf("envelope truncated before nonce")
+	}
+
+	nonce, ciphertext := rest[:nonceSize], rest[nonceSize:]
+
+	var plaintext []byte
+
+	if plaintext, err = gcm.Open(nil, nonce, ciphertext, nil); err != nil {
+		return nil, err
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,77 @@
+package util
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func TestEncryptDecryptEnvelopeRoundTrip(t *testing.T) {
+	priv, pub, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	// Larger than the RSA modulus minus OAEP overhead, which is what
+	// EncryptWithPublicKey alone cannot handle.
+	plaintext := make([]byte, 1<<20)
+
+	if _, err := rand.Read(plaintext); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+
+	ciphertext, err := EncryptEnvelope(plaintext, pub)
+	if err != nil {
+		t.Fatalf("EncryptEnvelope: %v", err)
+	}
+
+	decrypted, err := DecryptEnvelope(ciphertext, priv)
+	if err != nil {
+		t.Fatalf("DecryptEnvelope: %v", err)
+	}
+
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Fatalf("round trip mismatch: got %d bytes, want %d bytes", len(decrypted), len(plaintext))
+	}
+}
+
+func TestDecryptEnvelopeWrongPrivateKey(t *testing.T) {
+	_, pub, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	otherPriv, _, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	ciphertext, err := EncryptEnvelope([]byte("top secret"), pub)
+	if err != nil {
+		t.Fatalf("EncryptEnvelope: %v", err)
+	}
+
+	if _, err := DecryptEnvelope(ciphertext, otherPriv); err == nil {
+		t.Fatalf("DecryptEnvelope with the wrong private key: got nil error, want error")
+	}
+}
+
+func TestDecryptEnvelopeRejectsBadMagic(t *testing.T) {
+	priv, pub, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	ciphertext, err := EncryptEnvelope([]byte("top secret"), pub)
+	if err != nil {
+		t.Fatalf("EncryptEnvelope: %v", err)
+	}
+
+	tampered := make([]byte, len(ciphertext))
+	copy(tampered, ciphertext)
+	tampered[0] ^= 0xFF
+
+	if _, err := DecryptEnvelope(tampered, priv); err == nil {
+		t.Fatalf("DecryptEnvelope with corrupted magic: got nil error, want error")
+	}
+}
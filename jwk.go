@@ -0,0 +1,150 @@
+package util
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// JWK is an RFC 7517/7518 JSON Web Key for an RSA key pair. Only the fields
+// relevant to kty=RSA are represented.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	D   string `json:"d,omitempty"`
+	P   string `json:"p,omitempty"`
+	Q   string `json:"q,omitempty"`
+	Dp  string `json:"dp,omitempty"`
+	Dq  string `json:"dq,omitempty"`
+	Qi  string `json:"qi,omitempty"`
+}
+
+// JWKSet is an RFC 7517 JWK Set document.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKOption sets an optional field, such as alg or use, on a JWK produced by
+// PublicKeyToJWK or PrivateKeyToJWK.
+type JWKOption func(*JWK)
+
+// WithJWKAlg sets the JWK's alg field.
+func WithJWKAlg(alg string) JWKOption {
+	return func(jwk *JWK) { jwk.Alg = alg }
+}
+
+// WithJWKUse sets the JWK's use field.
+func WithJWKUse(use string) JWKOption {
+	return func(jwk *JWK) { jwk.Use = use }
+}
+
+// jwkEncode base64url-encodes b without padding, per RFC 7518 section 2.
+func jwkEncode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// jwkDecode reverses jwkEncode.
+func jwkDecode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// PublicKeyToJWK converts an RSA public key to a JSON-encoded JWK.
+func PublicKeyToJWK(pub *rsa.PublicKey, kid string, opts ...JWKOption) ([]byte, error) {
+	jwk := JWK{
+		Kty: "RSA",
+		Kid: kid,
+		N:   jwkEncode(pub.N.Bytes()),
+		E:   jwkEncode(big.NewInt(int64(pub.E)).Bytes()),
+	}
+
+	for _, opt := range opts {
+		opt(&jwk)
+	}
+
+	return json.Marshal(jwk)
+}
+
+// PrivateKeyToJWK converts an RSA private key to a JSON-encoded JWK,
+// including the d field and, for two-prime keys, the p, q, dp, dq, and qi
+// fields. Keys with more than two primes get no p, q, dp, dq, or qi.
+func PrivateKeyToJWK(priv *rsa.PrivateKey, kid string, opts ...JWKOption) ([]byte, error) {
+	priv.Precompute()
+
+	jwk := JWK{
+		Kty: "RSA",
+		Kid: kid,
+		N:   jwkEncode(priv.N.Bytes()),
+		E:   jwkEncode(big.NewInt(int64(priv.E)).Bytes()),
+		D:   jwkEncode(priv.D.Bytes()),
+	}
+
+	if len(priv.Primes) == 2 {
+		jwk.P = jwkEncode(priv.Primes[0].Bytes())
+		jwk.Q = jwkEncode(priv.Primes[1].Bytes())
+		jwk.Dp = jwkEncode(priv.Precomputed.Dp.Bytes())
+		jwk.Dq = jwkEncode(priv.Precomputed.Dq.Bytes())
+		jwk.Qi = jwkEncode(priv.Precomputed.Qinv.Bytes())
+	}
+
+	for _, opt := range opts {
+		opt(&jwk)
+	}
+
+	return json.Marshal(jwk)
+}
+
+// JWKToPublicKey parses a JSON-encoded RSA JWK into a public key.
+func JWKToPublicKey(data []byte) (*rsa.PublicKey, error) {
+	var err error
+	var jwk JWK
+
+	if err = json.Unmarshal(data, &jwk); err != nil {
+		return nil, err
+	}
+
+	if jwk.Kty != "RSA" {
+		return nil, fmt.Errorf("unsupported kty %q", jwk.Kty)
+	}
+
+	var nBytes, eBytes []byte
+
+	if nBytes, err = jwkDecode(jwk.N); err != nil {
+		return nil, err
+	}
+
+	if eBytes, err = jwkDecode(jwk.E); err != nil {
+		return nil, err
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
+
+// NewJWKSet builds a JWKSet from the given keys.
+func NewJWKSet(keys ...JWK) *JWKSet {
+	return &JWKSet{Keys: keys}
+}
+
+// MarshalJWKSet marshals a JWKSet to its JSON {"keys":[...]} form.
+func MarshalJWKSet(set *JWKSet) ([]byte, error) {
+	return json.Marshal(set)
+}
+
+// UnmarshalJWKSet parses a JSON {"keys":[...]} document into a JWKSet.
+func UnmarshalJWKSet(data []byte) (*JWKSet, error) {
+	var set JWKSet
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	return &set, nil
+}
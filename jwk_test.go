@@ -0,0 +1,138 @@
+package util
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPublicKeyToJWKRoundTrip(t *testing.T) {
+	_, pub, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	encoded, err := PublicKeyToJWK(pub, "test-kid", WithJWKAlg("RS256"), WithJWKUse("sig"))
+	if err != nil {
+		t.Fatalf("PublicKeyToJWK: %v", err)
+	}
+
+	var jwk JWK
+
+	if err := json.Unmarshal(encoded, &jwk); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	if jwk.Kty != "RSA" {
+		t.Fatalf("Kty = %q, want RSA", jwk.Kty)
+	}
+
+	if jwk.Kid != "test-kid" {
+		t.Fatalf("Kid = %q, want test-kid", jwk.Kid)
+	}
+
+	if jwk.Alg != "RS256" {
+		t.Fatalf("Alg = %q, want RS256", jwk.Alg)
+	}
+
+	if jwk.Use != "sig" {
+		t.Fatalf("Use = %q, want sig", jwk.Use)
+	}
+
+	decoded, err := JWKToPublicKey(encoded)
+	if err != nil {
+		t.Fatalf("JWKToPublicKey: %v", err)
+	}
+
+	if !decoded.Equal(pub) {
+		t.Fatalf("round trip produced a different public key")
+	}
+}
+
+func TestPrivateKeyToJWKIncludesCRTParams(t *testing.T) {
+	priv, _, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	encoded, err := PrivateKeyToJWK(priv, "test-kid")
+	if err != nil {
+		t.Fatalf("PrivateKeyToJWK: %v", err)
+	}
+
+	var jwk JWK
+
+	if err := json.Unmarshal(encoded, &jwk); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	for name, field := range map[string]string{
+		"d": jwk.D, "p": jwk.P, "q": jwk.Q, "dp": jwk.Dp, "dq": jwk.Dq, "qi": jwk.Qi,
+	} {
+		if field == "" {
+			t.Fatalf("field %q is empty, want a base64url value", name)
+		}
+	}
+
+	// The public half of a private JWK must still parse as the matching
+	// public key.
+	pub, err := JWKToPublicKey(encoded)
+	if err != nil {
+		t.Fatalf("JWKToPublicKey: %v", err)
+	}
+
+	if !pub.Equal(&priv.PublicKey) {
+		t.Fatalf("public key embedded in the private JWK does not match")
+	}
+}
+
+func TestJWKSetMarshalUnmarshalRoundTrip(t *testing.T) {
+	_, pub1, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	_, pub2, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	encoded1, err := PublicKeyToJWK(pub1, "kid-1")
+	if err != nil {
+		t.Fatalf("PublicKeyToJWK: %v", err)
+	}
+
+	encoded2, err := PublicKeyToJWK(pub2, "kid-2")
+	if err != nil {
+		t.Fatalf("PublicKeyToJWK: %v", err)
+	}
+
+	var jwk1, jwk2 JWK
+
+	if err := json.Unmarshal(encoded1, &jwk1); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	if err := json.Unmarshal(encoded2, &jwk2); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	set := NewJWKSet(jwk1, jwk2)
+
+	data, err := MarshalJWKSet(set)
+	if err != nil {
+		t.Fatalf("MarshalJWKSet: %v", err)
+	}
+
+	parsedSet, err := UnmarshalJWKSet(data)
+	if err != nil {
+		t.Fatalf("UnmarshalJWKSet: %v", err)
+	}
+
+	if len(parsedSet.Keys) != 2 {
+		t.Fatalf("len(parsedSet.Keys) = %d, want 2", len(parsedSet.Keys))
+	}
+
+	if parsedSet.Keys[0].Kid != "kid-1" || parsedSet.Keys[1].Kid != "kid-2" {
+		t.Fatalf("unexpected kids: %q, %q", parsedSet.Keys[0].Kid, parsedSet.Keys[1].Kid)
+	}
+}
@@ -0,0 +1,292 @@
+package util
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// aesV2Magic identifies the header produced by EncryptAESv2. Legacy
+// EncryptAES output has no such header, so DecryptAESv2 rejects it outright
+// on magic mismatch.
+const aesV2Magic = "UTLK"
+
+// aesV2Version is the current EncryptAESv2 header version.
+const aesV2Version byte = 1
+
+const (
+	kdfIDScrypt   byte = 1
+	kdfIDArgon2id byte = 2
+)
+
+const aesV2SaltSize = 16
+
+// KDF derives a key of keyLen bytes from a passphrase and salt.
+type KDF interface {
+	Derive(passphrase, salt []byte, keyLen int) ([]byte, error)
+}
+
+// ScryptKDF derives keys using scrypt.
+type ScryptKDF struct {
+	N int
+	R int
+	P int
+}
+
+// NewScryptKDF returns a ScryptKDF with the recommended parameters
+// N=32768, r=8, p=1.
+func NewScryptKDF() *ScryptKDF {
+	return &ScryptKDF{N: 32768, R: 8, P: 1}
+}
+
+// Derive implements KDF.
+func (k *ScryptKDF) Derive(passphrase, salt []byte, keyLen int) ([]byte, error) {
+	return scrypt.Key(passphrase, salt, k.N, k.R, k.P, keyLen)
+}
+
+// Argon2idKDF derives keys using Argon2id.
+type Argon2idKDF struct {
+	Time      uint32
+	MemoryKiB uint32
+	Threads   uint8
+}
+
+// NewArgon2idKDF returns an Argon2idKDF with the recommended defaults:
+// time=1, memory=64MiB, threads=4.
+func NewArgon2idKDF() *Argon2idKDF {
+	return &Argon2idKDF{Time: 1, MemoryKiB: 64 * 1024, Threads: 4}
+}
+
+// Derive implements KDF.
+func (k *Argon2idKDF) Derive(passphrase, salt []byte, keyLen int) ([]byte, error) {
+	return argon2.IDKey(passphrase, salt, k.Time, k.MemoryKiB, k.Threads, uint32(keyLen)), nil
+}
+
+// encodeKDFParams packs a KDF's tunables into the 4-byte parameter blob
+// carried in the EncryptAESv2 header. It returns an error rather than
+// silently truncating when a parameter falls outside the range the blob can
+// represent, so callers with aggressive KDF settings (e.g. Argon2id memory
+// above 65535 MiB) fail loudly instead of being encoded with a silently
+// weaker value.
+//
+// For scrypt: 1-byte log2(N), 1-byte r, 1-byte p, 1 byte reserved.
+// For Argon2id: 1-byte time, 2-byte memory in MiB (big-endian), 1-byte
+// threads.
+func encodeKDFParams(kdf KDF) (byte, [4]byte, error) {
+	var params [4]byte
+
+	switch k := kdf.(type) {
+	case *ScryptKDF:
+		if k.N < 2 || k.N&(k.N-1) != 0 {
+			return 0, params, fmt.Errorf("scrypt N must be a power of two greater than 1, got %d", k.N)
+		}
+
+		logN := 0
+
+		for n := k.N; n > 1; n >>= 1 {
+			logN++
+		}
+
+		if logN > 0xFF {
+			return 0, params, fmt.Errorf("scrypt N too large to encode: %d", k.N)
+		}
+
+		if k.R <= 0 || k.R > 0xFF {
+			return 0, params, fmt.Errorf("scrypt r out of encodable range [1,255]: %d", k.R)
+		}
+
+		if k.P <= 0 || k.P > 0xFF {
+			return 0, params, fmt.Errorf("scrypt p out of encodable range [1,255]: %d", k.P)
+		}
+
+		params[0] = byte(logN)
+		params[1] = byte(k.R)
+		params[2] = byte(k.P)
+
+		return kdfIDScrypt, params, nil
+	case *Argon2idKDF:
+		if k.Time == 0 || k.Time > 0xFF {
+			return 0, params, fmt.Errorf("argon2id time out of encodable range [1,255]: %d", k.Time)
+		}
+
+		if k.MemoryKiB == 0 || k.MemoryKiB%1024 != 0 {
+			return 0, params, fmt.Errorf("argon2id memory must be a positive whole number of MiB, got %d KiB", k.MemoryKiB)
+		}
+
+		memoryMiB := k.MemoryKiB / 1024
+
+		if memoryMiB > 0xFFFF {
+			return 0, params, fmt.Errorf("argon2id memory out of encodable range [1,65535] MiB: %d", memoryMiB)
+		}
+
+		if k.Threads == 0 {
+			return 0, params, fmt.Errorf("argon2id threads must be at least 1")
+		}
+
+		params[0] = byte(k.Time)
+		binary.BigEndian.PutUint16(params[1:3], uint16(memoryMiB))
+		params[3] = k.Threads
+
+		return kdfIDArgon2id, params, nil
+	default:
+		return 0, params, fmt.Errorf("unsupported KDF type %T", kdf)
+	}
+}
+
+// decodeKDFParams reconstructs the KDF recorded in an EncryptAESv2 header.
+func decodeKDFParams(id byte, params [4]byte) (KDF, error) {
+	switch id {
+	case kdfIDScrypt:
+		return &ScryptKDF{N: 1 << params[0], R: int(params[1]), P: int(params[2])}, nil
+	case kdfIDArgon2id:
+		memoryMiB := binary.BigEndian.Uint16(params[1:3])
+		return &Argon2idKDF{Time: uint32(params[0]), MemoryKiB: uint32(memoryMiB) * 1024, Threads: params[3]}, nil
+	default:
+		return nil, fmt.Errorf("unsupported KDF id %d", id)
+	}
+}
+
+// EncryptAESv2 encrypts data with AES-256-GCM, deriving the key from
+// passphrase and a fresh random salt via kdf. Unlike EncryptAES, the key is
+// never a raw SHA-256 of the passphrase: the KDF, its parameters, and the
+// salt are all recorded in a header so DecryptAESv2 can re-derive the same
+// key without the caller needing to remember which KDF or settings were
+// used.
+//
+// Header layout: 4-byte magic, 1-byte version, 1-byte KDF id, 16-byte salt,
+// 4-byte KDF parameter blob, followed by nonce||ciphertext.
+func EncryptAESv2(data []byte, passphrase string, kdf KDF) ([]byte, error) {
+	var err error
+
+	salt := make([]byte, aesV2SaltSize)
+
+	if _, err = io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	var key []byte
+
+	if key, err = kdf.Derive([]byte(passphrase), salt, 32); err != nil {
+		return nil, err
+	}
+
+	//
+	var block cipher.Block
+
+	if block, err = aes.NewCipher(key); err != nil {
+		return nil, err
+	}
+
+	//
+	var gcm cipher.AEAD
+
+	if gcm, err = cipher.NewGCM(block); err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	ciphertext := gcm.Seal(nonce, nonce, data, nil)
+
+	//
+	kdfID, params, err := encodeKDFParams(kdf)
+	if err != nil {
+		return nil, err
+	}
+
+	header := []byte(aesV2Magic)
+	header = append(header, aesV2Version, kdfID)
+	header = append(header, salt...)
+	header = append(header, params[:]...)
+
+	return append(header, ciphertext...), nil
+}
+
+// DecryptAESv2 reverses EncryptAESv2, re-deriving the key from the KDF and
+// parameters recorded in the header. Data produced by the legacy EncryptAES
+// (which carries no header) is rejected on magic mismatch.
+func DecryptAESv2(data []byte, passphrase string) ([]byte, error) {
+	headerLen := len(aesV2Magic) + 1 + 1 + aesV2SaltSize + 4
+
+	if len(data) < headerLen {
+		return nil, fmt.Errorf("data is not a valid EncryptAESv2 payload")
+	}
+
+	if string(data[:len(aesV2Magic)]) != aesV2Magic {
+		return nil, fmt.Errorf("unrecognized magic: not an EncryptAESv2 payload")
+	}
+
+	pos := len(aesV2Magic)
+
+	version := data[pos]
+	pos++
+
+	if version != aesV2Version {
+		return nil, fmt.Errorf("unsupported EncryptAESv2 version %d", version)
+	}
+
+	kdfID := data[pos]
+	pos++
+
+	salt := data[pos : pos+aesV2SaltSize]
+	pos += aesV2SaltSize
+
+	var params [4]byte
+	copy(params[:], data[pos:pos+4])
+	pos += 4
+
+	//
+	var err error
+	var kdf KDF
+
+	if kdf, err = decodeKDFParams(kdfID, params); err != nil {
+		return nil, err
+	}
+
+	var key []byte
+
+	if key, err = kdf.Derive([]byte(passphrase), salt, 32); err != nil {
+		return nil, err
+	}
+
+	//
+	var block cipher.Block
+
+	if block, err = aes.NewCipher(key); err != nil {
+		return nil, err
+	}
+
+	//
+	var gcm cipher.AEAD
+
+	if gcm, err = cipher.NewGCM(block); err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+	rest := data[pos:]
+
+	if len(rest) < nonceSize {
+		return nil, fmt.Errorf("data size is less than nonceSize")
+	}
+
+	nonce, ciphertext := rest[:nonceSize], rest[nonceSize:]
+
+	var plaintext []byte
+
+	if plaintext, err = gcm.Open(nil, nonce, ciphertext, nil); err != nil {
+		return nil, err
+	}
+
+	return plaintext, nil
+}
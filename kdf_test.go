@@ -0,0 +1,96 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncryptDecryptAESv2RoundTrip(t *testing.T) {
+	plaintext := []byte("the quick brown fox jumps over the lazy dog")
+
+	for name, kdf := range map[string]KDF{
+		"scrypt-default":   NewScryptKDF(),
+		"argon2id-default": NewArgon2idKDF(),
+		"argon2id-custom":  &Argon2idKDF{Time: 2, MemoryKiB: 32 * 1024, Threads: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ciphertext, err := EncryptAESv2(plaintext, "hunter2", kdf)
+			if err != nil {
+				t.Fatalf("EncryptAESv2: %v", err)
+			}
+
+			decrypted, err := DecryptAESv2(ciphertext, "hunter2")
+			if err != nil {
+				t.Fatalf("DecryptAESv2: %v", err)
+			}
+
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Fatalf("round trip mismatch: got %q, want %q", decrypted, plaintext)
+			}
+		})
+	}
+}
+
+func TestDecryptAESv2WrongPassphrase(t *testing.T) {
+	ciphertext, err := EncryptAESv2([]byte("secret"), "correct", NewScryptKDF())
+	if err != nil {
+		t.Fatalf("EncryptAESv2: %v", err)
+	}
+
+	if _, err := DecryptAESv2(ciphertext, "wrong"); err == nil {
+		t.Fatalf("DecryptAESv2 with wrong passphrase: got nil error, want error")
+	}
+}
+
+func TestDecryptAESv2RejectsLegacyMagic(t *testing.T) {
+	legacy, err := EncryptAES([]byte("secret"), "passphrase")
+	if err != nil {
+		t.Fatalf("EncryptAES: %v", err)
+	}
+
+	if _, err := DecryptAESv2(legacy, "passphrase"); err == nil {
+		t.Fatalf("DecryptAESv2 on legacy EncryptAES output: got nil error, want error")
+	}
+}
+
+func TestEncodeKDFParamsRoundTripsLargeArgon2idMemory(t *testing.T) {
+	// 256 MiB would not fit in a single byte; the 2-byte MiB field holds it,
+	// so it must encode without error and decode to the same value.
+	kdf := &Argon2idKDF{Time: 1, MemoryKiB: 256 * 1024, Threads: 4}
+
+	if _, _, err := encodeKDFParams(kdf); err != nil {
+		t.Fatalf("encodeKDFParams(256MiB) = %v, want nil error now that the field is wide enough", err)
+	}
+
+	id, params, err := encodeKDFParams(kdf)
+	if err != nil {
+		t.Fatalf("encodeKDFParams: %v", err)
+	}
+
+	decoded, err := decodeKDFParams(id, params)
+	if err != nil {
+		t.Fatalf("decodeKDFParams: %v", err)
+	}
+
+	got := decoded.(*Argon2idKDF)
+
+	if got.MemoryKiB != kdf.MemoryKiB {
+		t.Fatalf("round trip mismatch: got MemoryKiB=%d, want %d", got.MemoryKiB, kdf.MemoryKiB)
+	}
+}
+
+func TestEncodeKDFParamsRejectsNonWholeMiBMemory(t *testing.T) {
+	kdf := &Argon2idKDF{Time: 1, MemoryKiB: 1500, Threads: 4}
+
+	if _, _, err := encodeKDFParams(kdf); err == nil {
+		t.Fatalf("encodeKDFParams(1500 KiB) = nil error, want error for non-whole-MiB memory")
+	}
+}
+
+func TestEncodeKDFParamsRejectsOutOfRangeMemory(t *testing.T) {
+	kdf := &Argon2idKDF{Time: 1, MemoryKiB: 70000 * 1024, Threads: 4}
+
+	if _, _, err := encodeKDFParams(kdf); err == nil {
+		t.Fatalf("encodeKDFParams(70000 MiB) = nil error, want error for out-of-range memory")
+	}
+}
@@ -0,0 +1,213 @@
+package util
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// Signer signs data with a private key.
+type Signer interface {
+	Sign(data []byte) ([]byte, error)
+}
+
+// Verifier verifies data against a signature with a public key.
+type Verifier interface {
+	Verify(data []byte, sig []byte) error
+}
+
+// rsaPKCS1v15Signer signs with RSA-PKCS1v15-SHA256, the same scheme as
+// SignSignature.
+type rsaPKCS1v15Signer struct {
+	priv *rsa.PrivateKey
+}
+
+// NewRSAPKCS1v15Signer returns a Signer using RSA-PKCS1v15-SHA256.
+func NewRSAPKCS1v15Signer(priv *rsa.PrivateKey) *rsaPKCS1v15Signer {
+	return &rsaPKCS1v15Signer{priv: priv}
+}
+
+// Sign implements Signer.
+func (s *rsaPKCS1v15Signer) Sign(data []byte) ([]byte, error) {
+	return SignSignature(s.priv, data)
+}
+
+// rsaPKCS1v15Verifier verifies RSA-PKCS1v15-SHA256 signatures.
+type rsaPKCS1v15Verifier struct {
+	pub *rsa.PublicKey
+}
+
+// NewRSAPKCS1v15Verifier returns a Verifier using RSA-PKCS1v15-SHA256.
+func NewRSAPKCS1v15Verifier(pub *rsa.PublicKey) *rsaPKCS1v15Verifier {
+	return &rsaPKCS1v15Verifier{pub: pub}
+}
+
+// Verify implements Verifier.
+func (v *rsaPKCS1v15Verifier) Verify(data []byte, sig []byte) error {
+	return VerifySignature(v.pub, data, sig)
+}
+
+// rsaPSSSigner signs with RSA-PSS-SHA256.
+type rsaPSSSigner struct {
+	priv *rsa.PrivateKey
+}
+
+// NewRSAPSSSigner returns a Signer using RSA-PSS-SHA256.
+func NewRSAPSSSigner(priv *rsa.PrivateKey) *rsaPSSSigner {
+	return &rsaPSSSigner{priv: priv}
+}
+
+// Sign implements Signer.
+func (s *rsaPSSSigner) Sign(data []byte) ([]byte, error) {
+	digest := sha256.Sum256(data)
+	return rsa.SignPSS(rand.Reader, s.priv, crypto.SHA256, digest[:], &rsa.PSSOptions{
+		SaltLength: rsa.PSSSaltLengthEqualsHash,
+	})
+}
+
+// rsaPSSVerifier verifies RSA-PSS-SHA256 signatures.
+type rsaPSSVerifier struct {
+	pub *rsa.PublicKey
+}
+
+// NewRSAPSSVerifier returns a Verifier using RSA-PSS-SHA256.
+func NewRSAPSSVerifier(pub *rsa.PublicKey) *rsaPSSVerifier {
+	return &rsaPSSVerifier{pub: pub}
+}
+
+// Verify implements Verifier.
+func (v *rsaPSSVerifier) Verify(data []byte, sig []byte) error {
+	digest := sha256.Sum256(data)
+	return rsa.VerifyPSS(v.pub, crypto.SHA256, digest[:], sig, &rsa.PSSOptions{
+		SaltLength: rsa.PSSSaltLengthEqualsHash,
+	})
+}
+
+// ecdsaSigner signs a SHA-256 digest with ECDSA over the private key's curve
+// (for example P-256).
+type ecdsaSigner struct {
+	priv *ecdsa.PrivateKey
+}
+
+// NewECDSASigner returns a Signer using ECDSA over the private key's curve.
+func NewECDSASigner(priv *ecdsa.PrivateKey) *ecdsaSigner {
+	return &ecdsaSigner{priv: priv}
+}
+
+// Sign implements Signer.
+func (s *ecdsaSigner) Sign(data []byte) ([]byte, error) {
+	digest := sha256.Sum256(data)
+	return ecdsa.SignASN1(rand.Reader, s.priv, digest[:])
+}
+
+// ecdsaVerifier verifies ECDSA signatures.
+type ecdsaVerifier struct {
+	pub *ecdsa.PublicKey
+}
+
+// NewECDSAVerifier returns a Verifier using ECDSA over the public key's curve.
+func NewECDSAVerifier(pub *ecdsa.PublicKey) *ecdsaVerifier {
+	return &ecdsaVerifier{pub: pub}
+}
+
+// Verify implements Verifier.
+func (v *ecdsaVerifier) Verify(data []byte, sig []byte) error {
+	digest := sha256.Sum256(data)
+
+	if !ecdsa.VerifyASN1(v.pub, digest[:], sig) {
+		return fmt.Errorf("ecdsa: signature verification failed")
+	}
+
+	return nil
+}
+
+// ed25519Signer signs with Ed25519.
+type ed25519Signer struct {
+	priv ed25519.PrivateKey
+}
+
+// NewEd25519Signer returns a Signer using Ed25519.
+func NewEd25519Signer(priv ed25519.PrivateKey) *ed25519Signer {
+	return &ed25519Signer{priv: priv}
+}
+
+// Sign implements Signer.
+func (s *ed25519Signer) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(s.priv, data), nil
+}
+
+// ed25519Verifier verifies Ed25519 signatures.
+type ed25519Verifier struct {
+	pub ed25519.PublicKey
+}
+
+// NewEd25519Verifier returns a Verifier using Ed25519.
+func NewEd25519Verifier(pub ed25519.PublicKey) *ed25519Verifier {
+	return &ed25519Verifier{pub: pub}
+}
+
+// Verify implements Verifier.
+func (v *ed25519Verifier) Verify(data []byte, sig []byte) error {
+	if !ed25519.Verify(v.pub, data, sig) {
+		return fmt.Errorf("ed25519: signature verification failed")
+	}
+
+	return nil
+}
+
+// SignerFromPEM parses a PEM-encoded private key and returns a Signer for
+// it, picking the algorithm from the key type: RSA keys get RSA-PSS-SHA256,
+// EC keys get ECDSA, and Ed25519 keys (only representable as PKCS8) get
+// Ed25519.
+func SignerFromPEM(data []byte) (Signer, error) {
+	var err error
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		var priv *rsa.PrivateKey
+
+		if priv, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, err
+		}
+
+		return NewRSAPSSSigner(priv), nil
+	case "EC PRIVATE KEY":
+		var priv *ecdsa.PrivateKey
+
+		if priv, err = x509.ParseECPrivateKey(block.Bytes); err != nil {
+			return nil, err
+		}
+
+		return NewECDSASigner(priv), nil
+	case "PRIVATE KEY":
+		var ifc interface{}
+
+		if ifc, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+			return nil, err
+		}
+
+		switch key := ifc.(type) {
+		case *rsa.PrivateKey:
+			return NewRSAPSSSigner(key), nil
+		case *ecdsa.PrivateKey:
+			return NewECDSASigner(key), nil
+		case ed25519.PrivateKey:
+			return NewEd25519Signer(key), nil
+		default:
+			return nil, fmt.Errorf("unsupported key type %T in PKCS8 block", ifc)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported %s block.Type", block.Type)
+	}
+}
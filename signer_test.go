@@ -0,0 +1,124 @@
+package util
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+)
+
+func TestRSAPKCS1v15SignerVerifier(t *testing.T) {
+	priv, pub, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	data := []byte("sign me")
+
+	sig, err := NewRSAPKCS1v15Signer(priv).Sign(data)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	if err := NewRSAPKCS1v15Verifier(pub).Verify(data, sig); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if err := NewRSAPKCS1v15Verifier(pub).Verify([]byte("tampered"), sig); err == nil {
+		t.Fatalf("Verify on tampered data: got nil error, want error")
+	}
+}
+
+func TestRSAPSSSignerVerifier(t *testing.T) {
+	priv, pub, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	data := []byte("sign me")
+
+	sig, err := NewRSAPSSSigner(priv).Sign(data)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	if err := NewRSAPSSVerifier(pub).Verify(data, sig); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if err := NewRSAPSSVerifier(pub).Verify([]byte("tampered"), sig); err == nil {
+		t.Fatalf("Verify on tampered data: got nil error, want error")
+	}
+}
+
+func TestECDSASignerVerifier(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey: %v", err)
+	}
+
+	data := []byte("sign me")
+
+	sig, err := NewECDSASigner(priv).Sign(data)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	if err := NewECDSAVerifier(&priv.PublicKey).Verify(data, sig); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if err := NewECDSAVerifier(&priv.PublicKey).Verify([]byte("tampered"), sig); err == nil {
+		t.Fatalf("Verify on tampered data: got nil error, want error")
+	}
+}
+
+func TestEd25519SignerVerifier(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey: %v", err)
+	}
+
+	data := []byte("sign me")
+
+	sig, err := NewEd25519Signer(priv).Sign(data)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	if err := NewEd25519Verifier(pub).Verify(data, sig); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if err := NewEd25519Verifier(pub).Verify([]byte("tampered"), sig); err == nil {
+		t.Fatalf("Verify on tampered data: got nil error, want error")
+	}
+}
+
+func TestSignerFromPEM(t *testing.T) {
+	priv, _, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	signer, err := SignerFromPEM(PrivateKeyToBytes(priv))
+	if err != nil {
+		t.Fatalf("SignerFromPEM: %v", err)
+	}
+
+	if _, ok := signer.(*rsaPSSSigner); !ok {
+		t.Fatalf("SignerFromPEM on an RSA PRIVATE KEY block returned %T, want *rsaPSSSigner", signer)
+	}
+
+	data := []byte("sign me")
+
+	sig, err := signer.Sign(data)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	if err := NewRSAPSSVerifier(&priv.PublicKey).Verify(data, sig); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package util
+
+import (
+	"crypto/rsa"
+	"encoding/pem"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// PublicKeyToSSHAuthorizedKey converts an RSA public key to the single-line
+// "ssh-rsa AAAA..." form used by authorized_keys files.
+func PublicKeyToSSHAuthorizedKey(pub *rsa.PublicKey) ([]byte, error) {
+	var err error
+	var sshPub ssh.PublicKey
+
+	if sshPub, err = ssh.NewPublicKey(pub); err != nil {
+		return nil, err
+	}
+
+	return ssh.MarshalAuthorizedKey(sshPub), nil
+}
+
+// PrivateKeyToOpenSSHPEM converts an RSA private key to an "OPENSSH PRIVATE
+// KEY" PEM block, optionally encrypted with the given passphrase. An empty
+// passphrase produces an unencrypted block.
+func PrivateKeyToOpenSSHPEM(priv *rsa.PrivateKey, passphrase string) ([]byte, error) {
+	var err error
+	var block *pem.Block
+
+	if passphrase == "" {
+		if block, err = ssh.MarshalPrivateKey(priv, ""); err != nil {
+			return nil, err
+		}
+	} else {
+		if block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte(passphrase)); err != nil {
+			return nil, err
+		}
+	}
+
+	if block == nil {
+		return nil, fmt.Errorf("failed to marshal OpenSSH private key")
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
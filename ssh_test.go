@@ -0,0 +1,95 @@
+package util
+
+import (
+	"crypto/rsa"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestPublicKeyToSSHAuthorizedKey(t *testing.T) {
+	_, pub, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	authorizedKey, err := PublicKeyToSSHAuthorizedKey(pub)
+	if err != nil {
+		t.Fatalf("PublicKeyToSSHAuthorizedKey: %v", err)
+	}
+
+	if !strings.HasPrefix(string(authorizedKey), "ssh-rsa ") {
+		t.Fatalf("authorized key does not start with ssh-rsa: %q", authorizedKey)
+	}
+
+	parsed, _, _, _, err := ssh.ParseAuthorizedKey(authorizedKey)
+	if err != nil {
+		t.Fatalf("ssh.ParseAuthorizedKey: %v", err)
+	}
+
+	want, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("ssh.NewPublicKey: %v", err)
+	}
+
+	if string(parsed.Marshal()) != string(want.Marshal()) {
+		t.Fatalf("parsed authorized key does not match the original public key")
+	}
+}
+
+func TestPrivateKeyToOpenSSHPEMRoundTrip(t *testing.T) {
+	priv, _, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	block, err := PrivateKeyToOpenSSHPEM(priv, "")
+	if err != nil {
+		t.Fatalf("PrivateKeyToOpenSSHPEM: %v", err)
+	}
+
+	parsed, err := ssh.ParseRawPrivateKey(block)
+	if err != nil {
+		t.Fatalf("ssh.ParseRawPrivateKey: %v", err)
+	}
+
+	parsedPriv, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		t.Fatalf("ssh.ParseRawPrivateKey returned %T, want *rsa.PrivateKey", parsed)
+	}
+
+	if !parsedPriv.Equal(priv) {
+		t.Fatalf("round trip produced a different private key")
+	}
+}
+
+func TestPrivateKeyToOpenSSHPEMWithPassphrase(t *testing.T) {
+	priv, _, err := GenerateKeyPair(2048)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair: %v", err)
+	}
+
+	block, err := PrivateKeyToOpenSSHPEM(priv, "hunter2")
+	if err != nil {
+		t.Fatalf("PrivateKeyToOpenSSHPEM: %v", err)
+	}
+
+	if _, err := ssh.ParseRawPrivateKey(block); err == nil {
+		t.Fatalf("ssh.ParseRawPrivateKey without a passphrase on an encrypted key: got nil error, want error")
+	}
+
+	parsed, err := ssh.ParseRawPrivateKeyWithPassphrase(block, []byte("hunter2"))
+	if err != nil {
+		t.Fatalf("ssh.ParseRawPrivateKeyWithPassphrase: %v", err)
+	}
+
+	parsedPriv, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		t.Fatalf("ssh.ParseRawPrivateKeyWithPassphrase returned %T, want *rsa.PrivateKey", parsed)
+	}
+
+	if !parsedPriv.Equal(priv) {
+		t.Fatalf("round trip produced a different private key")
+	}
+}
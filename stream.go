@@ -0,0 +1,284 @@
+package util
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// streamMagic identifies the header produced by EncryptAESStream.
+const streamMagic = "UTLF"
+
+// streamVersion is the current EncryptAESStream header version.
+const streamVersion byte = 1
+
+// defaultStreamChunkSize is the plaintext size of each sealed frame.
+const defaultStreamChunkSize = 64 * 1024
+
+// streamFinalFlag marks a frame's length prefix as the last frame in the
+// stream.
+const streamFinalFlag uint32 = 1 << 31
+
+// ErrTruncated is returned by DecryptAESStream when the input ends before a
+// final frame was seen, or when authentication fails on what was marked as
+// the final frame.
+var ErrTruncated = errors.New("util: stream truncated")
+
+// frameAAD binds a frame's final-flag and counter into the AEAD associated
+// data, so an attacker who truncates the stream and flips the final-flag bit
+// on the length prefix of an earlier frame changes the AAD used to verify
+// that frame's tag and fails authentication instead of being silently
+// accepted.
+func frameAAD(final bool, counter uint64) []byte {
+	aad := make([]byte, 9)
+
+	if final {
+		aad[0] = 1
+	}
+
+	binary.BigEndian.PutUint64(aad[1:], counter)
+
+	return aad
+}
+
+// EncryptAESStream reads src in fixed-size chunks, seals each chunk with
+// AES-256-GCM, and writes the result to dst without holding the whole
+// plaintext in memory. The AES key is derived from passphrase with scrypt;
+// the salt and KDF parameters are recorded in a header alongside the chunk
+// size, so DecryptAESStream can re-derive the same key.
+//
+// Each frame's nonce is a 4-byte random stream prefix (fixed for the whole
+// stream and stored in the header) followed by an 8-byte big-endian frame
+// counter, so no nonce is ever reused for a given key. The last frame's
+// length prefix has its high bit set so truncation can be detected; that
+// flag and the frame counter are also bound into the AEAD associated data,
+// so flipping the flag or reordering/dropping frames on an untrusted
+// ciphertext fails authentication rather than being silently accepted.
+func EncryptAESStream(dst io.Writer, src io.Reader, passphrase string) error {
+	var err error
+
+	kdf := NewScryptKDF()
+	salt := make([]byte, aesV2SaltSize)
+
+	if _, err = io.ReadFull(rand.Reader, salt); err != nil {
+		return err
+	}
+
+	var key []byte
+
+	if key, err = kdf.Derive([]byte(passphrase), salt, 32); err != nil {
+		return err
+	}
+
+	//
+	var block cipher.Block
+
+	if block, err = aes.NewCipher(key); err != nil {
+		return err
+	}
+
+	//
+	var gcm cipher.AEAD
+
+	if gcm, err = cipher.NewGCM(block); err != nil {
+		return err
+	}
+
+	noncePrefix := make([]byte, 4)
+
+	if _, err = io.ReadFull(rand.Reader, noncePrefix); err != nil {
+		return err
+	}
+
+	kdfID, params, err := encodeKDFParams(kdf)
+	if err != nil {
+		return err
+	}
+
+	header := []byte(streamMagic)
+	header = append(header, streamVersion, kdfID)
+	header = append(header, salt...)
+	header = append(header, params[:]...)
+	header = binary.BigEndian.AppendUint32(header, defaultStreamChunkSize)
+	header = append(header, noncePrefix...)
+
+	if _, err = dst.Write(header); err != nil {
+		return err
+	}
+
+	//
+	br := bufio.NewReaderSize(src, defaultStreamChunkSize)
+	buf := make([]byte, defaultStreamChunkSize)
+	nonce := make([]byte, gcm.NonceSize())
+	copy(nonce, noncePrefix)
+
+	var counter uint64
+
+	for {
+		var n int
+
+		if n, err = io.ReadFull(br, buf); err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return err
+		}
+
+		final := err == io.ErrUnexpectedEOF || err == io.EOF
+
+		if !final {
+			if _, peekErr := br.Peek(1); peekErr != nil {
+				final = true
+			}
+		}
+
+		binary.BigEndian.PutUint64(nonce[4:], counter)
+		aad := frameAAD(final, counter)
+		counter++
+
+		ciphertext := gcm.Seal(nil, nonce, buf[:n], aad)
+
+		length := uint32(len(ciphertext))
+
+		if final {
+			length |= streamFinalFlag
+		}
+
+		var lengthBuf [4]byte
+		binary.BigEndian.PutUint32(lengthBuf[:], length)
+
+		if _, err = dst.Write(lengthBuf[:]); err != nil {
+			return err
+		}
+
+		if _, err = dst.Write(ciphertext); err != nil {
+			return err
+		}
+
+		if final {
+			return nil
+		}
+	}
+}
+
+// DecryptAESStream reverses EncryptAESStream, re-deriving the key from the
+// header and verifying/writing each frame to dst in turn. If the input ends
+// without a final frame, or authentication fails on the final frame, it
+// returns ErrTruncated.
+func DecryptAESStream(dst io.Writer, src io.Reader, passphrase string) error {
+	headerLen := len(streamMagic) + 1 + 1 + aesV2SaltSize + 4 + 4 + 4
+	header := make([]byte, headerLen)
+
+	if _, err := io.ReadFull(src, header); err != nil {
+		return fmt.Errorf("failed to read stream header: %w", err)
+	}
+
+	pos := 0
+
+	if string(header[pos:pos+len(streamMagic)]) != streamMagic {
+		return fmt.Errorf("unrecognized magic: not an EncryptAESStream payload")
+	}
+	pos += len(streamMagic)
+
+	version := header[pos]
+	pos++
+
+	if version != streamVersion {
+		return fmt.Errorf("unsupported EncryptAESStream version %d", version)
+	}
+
+	kdfID := header[pos]
+	pos++
+
+	salt := header[pos : pos+aesV2SaltSize]
+	pos += aesV2SaltSize
+
+	var params [4]byte
+	copy(params[:], header[pos:pos+4])
+	pos += 4
+
+	chunkSize := binary.BigEndian.Uint32(header[pos : pos+4])
+	pos += 4
+
+	noncePrefix := header[pos : pos+4]
+
+	//
+	var err error
+	var kdf KDF
+
+	if kdf, err = decodeKDFParams(kdfID, params); err != nil {
+		return err
+	}
+
+	var key []byte
+
+	if key, err = kdf.Derive([]byte(passphrase), salt, 32); err != nil {
+		return err
+	}
+
+	//
+	var block cipher.Block
+
+	if block, err = aes.NewCipher(key); err != nil {
+		return err
+	}
+
+	//
+	var gcm cipher.AEAD
+
+	if gcm, err = cipher.NewGCM(block); err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	copy(nonce, noncePrefix)
+
+	maxCiphertext := int(chunkSize) + gcm.Overhead()
+	var counter uint64
+
+	for {
+		var lengthBuf [4]byte
+
+		if _, err = io.ReadFull(src, lengthBuf[:]); err != nil {
+			return ErrTruncated
+		}
+
+		length := binary.BigEndian.Uint32(lengthBuf[:])
+		final := length&streamFinalFlag != 0
+		length &^= streamFinalFlag
+
+		if int(length) > maxCiphertext {
+			return fmt.Errorf("frame length %d exceeds maximum %d", length, maxCiphertext)
+		}
+
+		ciphertext := make([]byte, length)
+
+		if _, err = io.ReadFull(src, ciphertext); err != nil {
+			return ErrTruncated
+		}
+
+		binary.BigEndian.PutUint64(nonce[4:], counter)
+		aad := frameAAD(final, counter)
+		counter++
+
+		var plaintext []byte
+
+		if plaintext, err = gcm.Open(nil, nonce, ciphertext, aad); err != nil {
+			if final {
+				return ErrTruncated
+			}
+
+			return err
+		}
+
+		if _, err = dst.Write(plaintext); err != nil {
+			return err
+		}
+
+		if final {
+			return nil
+		}
+	}
+}
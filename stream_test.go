@@ -0,0 +1,88 @@
+package util
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestEncryptDecryptAESStreamRoundTrip(t *testing.T) {
+	plaintext := make([]byte, 3*defaultStreamChunkSize+100)
+
+	if _, err := rand.Read(plaintext); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+
+	var ciphertext bytes.Buffer
+
+	if err := EncryptAESStream(&ciphertext, bytes.NewReader(plaintext), "correct horse battery staple"); err != nil {
+		t.Fatalf("EncryptAESStream: %v", err)
+	}
+
+	var decrypted bytes.Buffer
+
+	if err := DecryptAESStream(&decrypted, bytes.NewReader(ciphertext.Bytes()), "correct horse battery staple"); err != nil {
+		t.Fatalf("DecryptAESStream: %v", err)
+	}
+
+	if !bytes.Equal(decrypted.Bytes(), plaintext) {
+		t.Fatalf("round trip mismatch: got %d bytes, want %d bytes", decrypted.Len(), len(plaintext))
+	}
+}
+
+// TestDecryptAESStreamTruncated covers both accidental truncation (the
+// stream just runs out of bytes) and adversarial truncation (an attacker
+// cuts the stream after an intermediate frame and flips that frame's
+// final-flag bit to make it look complete). Both must surface ErrTruncated
+// rather than silently returning the truncated prefix with a nil error.
+func TestDecryptAESStreamTruncated(t *testing.T) {
+	plaintext := make([]byte, 3*defaultStreamChunkSize)
+
+	if _, err := rand.Read(plaintext); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+
+	var ciphertext bytes.Buffer
+
+	if err := EncryptAESStream(&ciphertext, bytes.NewReader(plaintext), "passphrase"); err != nil {
+		t.Fatalf("EncryptAESStream: %v", err)
+	}
+
+	full := ciphertext.Bytes()
+
+	headerLen := len(streamMagic) + 1 + 1 + aesV2SaltSize + 4 + 4 + 4
+	frameLengthPos := headerLen
+	frameLength := binary.BigEndian.Uint32(full[frameLengthPos:frameLengthPos+4]) &^ streamFinalFlag
+	firstFrameEnd := headerLen + 4 + int(frameLength)
+
+	truncated := make([]byte, firstFrameEnd)
+	copy(truncated, full[:firstFrameEnd])
+
+	t.Run("plain truncation", func(t *testing.T) {
+		var out bytes.Buffer
+
+		err := DecryptAESStream(&out, bytes.NewReader(truncated), "passphrase")
+		if !errors.Is(err, ErrTruncated) {
+			t.Fatalf("DecryptAESStream() = %v, want ErrTruncated", err)
+		}
+	})
+
+	t.Run("truncation with final-flag flipped", func(t *testing.T) {
+		tampered := make([]byte, len(truncated))
+		copy(tampered, truncated)
+		tampered[frameLengthPos] |= 0x80
+
+		var out bytes.Buffer
+
+		err := DecryptAESStream(&out, bytes.NewReader(tampered), "passphrase")
+		if !errors.Is(err, ErrTruncated) {
+			t.Fatalf("DecryptAESStream() = %v, want ErrTruncated", err)
+		}
+
+		if out.Len() != 0 {
+			t.Fatalf("DecryptAESStream wrote %d bytes of unverified output on truncated final frame", out.Len())
+		}
+	})
+}